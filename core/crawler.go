@@ -3,6 +3,7 @@ package core
 import (
 	"bufio"
 	"crypto/tls"
+	"encoding/json"
 	"fmt"
 	"github.com/gocolly/colly/v2"
 	"github.com/gocolly/colly/v2/extensions"
@@ -24,11 +25,46 @@ type Crawler struct {
 	Output   *Output
 	domainRe *regexp.Regexp
 
-	subSet  *stringset.StringFilter
-	awsSet  *stringset.StringFilter
-	jsSet   *stringset.StringFilter
-	urlSet  *stringset.StringFilter
-	formSet *stringset.StringFilter
+	subSet    *stringset.StringFilter
+	secretSet *stringset.StringFilter
+	jsSet     *stringset.StringFilter
+	urlSet    *stringset.StringFilter
+	formSet   *stringset.StringFilter
+
+	// queue is non-nil when --resume is used. It replaces the in-memory
+	// sets above with an on-disk frontier/dedup store so the crawl can
+	// survive a restart instead of starting over.
+	queue VisitQueue
+
+	// renderPool is non-nil when --render is used. It drives a headless
+	// Chromium instance to pick up links/forms/XHRs that only appear after
+	// JS execution.
+	renderPool *RenderPool
+
+	// format controls how emit() prints findings to stdout: "text" (the
+	// original `[tag] - value` lines), "json", or "ndjson".
+	format string
+
+	// controller is non-nil when --dashboard is used; notify forwards every
+	// emitted Finding to it for live counters/SSE.
+	controller *Controller
+	notify     func(Finding)
+
+	// scanner runs the pluggable secret/PII rule set (--rules) over every
+	// response and JS body; it always includes the built-in AWS-S3 rule.
+	scanner *Scanner
+
+	// limitRule is the *colly.LimitRule installed on C in NewCrawler.
+	// colly.Limit only ever appends rules and matches the first one for a
+	// given domain, so the dashboard's /api/limit mutates this pointer's
+	// fields in place rather than calling C.Limit again.
+	limitRule *colly.LimitRule
+
+	// httpTransport and extraHeaders mirror what's wired into C, so
+	// side-channel requests (passive seed discovery) reuse the same
+	// --proxy/--cookie/--header/--burp setup instead of going out raw.
+	httpTransport *http.Transport
+	extraHeaders  map[string]string
 
 	site   *url.URL
 	domain string
@@ -81,6 +117,11 @@ func NewCrawler(site *url.URL, cmd *cobra.Command) *Crawler {
 	}
 	c.WithTransport(tr)
 
+	// extraHeaders mirrors whatever gets set on every colly request below,
+	// so side-channel requests (passive seed discovery, etc.) that don't go
+	// through colly can still carry the same auth/cookie/header setup.
+	extraHeaders := map[string]string{}
+
 	// Get headers here to overwrite if "burp" flag used
 	burpFile, _ := cmd.Flags().GetString("burp")
 	if burpFile != "" {
@@ -94,9 +135,11 @@ func NewCrawler(site *url.URL, cmd *cobra.Command) *Crawler {
 				Logger.Errorf("Failed to Parse Raw Request in %s: %s", burpFile, err)
 			} else {
 				// Set cookie
+				rawCookie := GetRawCookie(req.Cookies())
 				c.OnRequest(func(r *colly.Request) {
-					r.Headers.Set("Cookie", GetRawCookie(req.Cookies()))
+					r.Headers.Set("Cookie", rawCookie)
 				})
+				extraHeaders["Cookie"] = rawCookie
 
 				// Set headers
 				c.OnRequest(func(r *colly.Request) {
@@ -104,7 +147,9 @@ func NewCrawler(site *url.URL, cmd *cobra.Command) *Crawler {
 						r.Headers.Set(strings.TrimSpace(k), strings.TrimSpace(v[0]))
 					}
 				})
-
+				for k, v := range req.Header {
+					extraHeaders[strings.TrimSpace(k)] = strings.TrimSpace(v[0])
+				}
 			}
 		}
 	}
@@ -115,6 +160,7 @@ func NewCrawler(site *url.URL, cmd *cobra.Command) *Crawler {
 		c.OnRequest(func(r *colly.Request) {
 			r.Headers.Set("Cookie", cookie)
 		})
+		extraHeaders["Cookie"] = cookie
 	}
 
 	// Set headers
@@ -127,6 +173,7 @@ func NewCrawler(site *url.URL, cmd *cobra.Command) *Crawler {
 			c.OnRequest(func(r *colly.Request) {
 				r.Headers.Set(headerKey, headerValue)
 			})
+			extraHeaders[headerKey] = headerValue
 		}
 	}
 
@@ -153,25 +200,35 @@ func NewCrawler(site *url.URL, cmd *cobra.Command) *Crawler {
 	}
 
 	// Init Output
+	format, _ := cmd.Flags().GetString("format")
+	if format == "" {
+		format = "text"
+	}
+	splitOutput, _ := cmd.Flags().GetBool("split-output")
+
 	var output *Output
 	outputFolder, _ := cmd.Flags().GetString("output")
 	if outputFolder != "" {
 		filename := strings.ReplaceAll(site.Hostname(), ".", "_")
-		output = NewOutput(outputFolder, filename)
+		output = NewOutput(outputFolder, filename, format, splitOutput)
 	}
 
 	// Set url whitelist regex
 	domainRe := regexp.MustCompile(domain)
 	c.URLFilters = append(c.URLFilters, domainRe)
 
-	// Set Limit Rule
-	err := c.Limit(&colly.LimitRule{
+	// Set Limit Rule. colly.Limit only ever appends to its internal rule
+	// slice and picks the first rule matching a domain, so a later call
+	// with the same DomainGlob would *not* replace this one — hang onto
+	// the pointer colly stored so the dashboard can mutate it in place
+	// instead (see Controller.handleLimit).
+	limitRule := &colly.LimitRule{
 		DomainGlob:  domain,
 		Parallelism: concurrent,
 		Delay:       time.Duration(delay) * time.Second,
 		RandomDelay: time.Duration(randomDelay) * time.Second,
-	})
-	if err != nil {
+	}
+	if err := c.Limit(limitRule); err != nil {
 		Logger.Errorf("Failed to set Limit Rule: %s", err)
 		os.Exit(1)
 	}
@@ -186,22 +243,169 @@ func NewCrawler(site *url.URL, cmd *cobra.Command) *Crawler {
 		c.DisallowedURLFilters = append(c.DisallowedURLFilters, regexp.MustCompile(blacklists))
 	}
 
-	return &Crawler{
-		cmd:      cmd,
-		C:        c,
-		site:     site,
-		domain:   domain,
-		Output:   output,
-		domainRe: domainRe,
-		urlSet:   stringset.NewStringFilter(),
-		subSet:   stringset.NewStringFilter(),
-		jsSet:    stringset.NewStringFilter(),
-		formSet:  stringset.NewStringFilter(),
-		awsSet:   stringset.NewStringFilter(),
+	// Resume support: swap the in-memory dedup sets for an on-disk queue
+	var queue VisitQueue
+	resumeDir, _ := cmd.Flags().GetString("resume")
+	if resumeDir != "" {
+		maxQueueSize, _ := cmd.Flags().GetInt("queue-max-size")
+		flushInterval, _ := cmd.Flags().GetInt("queue-flush-interval")
+		if err := os.MkdirAll(resumeDir, 0755); err != nil {
+			Logger.Errorf("Failed to create resume dir: %s", err)
+			os.Exit(1)
+		}
+		q, err := NewDiskVisitQueue(resumeDir, maxQueueSize, time.Duration(flushInterval)*time.Second)
+		if err != nil {
+			Logger.Errorf("Failed to open resume queue: %s", err)
+			os.Exit(1)
+		}
+		queue = q
+		Logger.Infof("Resuming crawl from: %s", resumeDir)
+	}
+
+	// Headless-render support: spin up a shared browser pool sized by
+	// --concurrent so --render never out-paces the plain HTTP crawl.
+	var renderPool *RenderPool
+	render, _ := cmd.Flags().GetBool("render")
+	if render {
+		renderWait, _ := cmd.Flags().GetString("render-wait")
+		renderTimeout, _ := cmd.Flags().GetInt("render-timeout")
+		if renderTimeout <= 0 {
+			renderTimeout = 30
+		}
+		renderPool = NewRenderPool(concurrent, renderWait, time.Duration(renderTimeout)*time.Second)
+	}
+
+	rulesFile, _ := cmd.Flags().GetString("rules")
+	scanner, err := NewScanner(rulesFile)
+	if err != nil {
+		Logger.Errorf("Failed to load secret rules: %s", err)
+		os.Exit(1)
+	}
+
+	crawler := &Crawler{
+		cmd:        cmd,
+		C:          c,
+		site:       site,
+		domain:     domain,
+		Output:     output,
+		domainRe:   domainRe,
+		urlSet:     stringset.NewStringFilter(),
+		subSet:     stringset.NewStringFilter(),
+		jsSet:      stringset.NewStringFilter(),
+		formSet:    stringset.NewStringFilter(),
+		secretSet:  stringset.NewStringFilter(),
+		queue:      queue,
+		renderPool: renderPool,
+		format:     format,
+		scanner:    scanner,
+		limitRule:  limitRule,
+
+		httpTransport: tr,
+		extraHeaders:  extraHeaders,
+	}
+
+	// Dashboard support: expose live counters/SSE/runtime controls over HTTP.
+	dashboardAddr, _ := cmd.Flags().GetString("dashboard")
+	if dashboardAddr != "" {
+		controller := NewController(crawler)
+		crawler.controller = controller
+		go controller.Serve(dashboardAddr)
+	}
+
+	return crawler
+}
+
+// emit is the single place a Finding becomes user-visible output: it
+// prints to stdout per --format and, if an Output is configured, persists
+// the same Finding to disk (respecting --split-output).
+func (crawler *Crawler) emit(finding Finding) {
+	finding.Ts = nowTimestamp()
+
+	switch crawler.format {
+	case "json", "ndjson":
+		data, err := json.Marshal(finding)
+		if err != nil {
+			Logger.Errorf("Failed to marshal finding: %s", err)
+			return
+		}
+		fmt.Println(string(data))
+	default:
+		fmt.Println(finding.line())
+	}
+
+	crawler.Output.WriteFinding(finding)
+
+	if crawler.notify != nil {
+		crawler.notify(finding)
+	}
+}
+
+// duplicateURL reports whether urlString was already visited/queued, and
+// marks it seen as a side effect (mirrors stringset.StringFilter.Duplicate).
+func (crawler *Crawler) duplicateURL(urlString string) bool {
+	if crawler.queue != nil {
+		alreadySeen, err := crawler.queue.Enqueue(urlString)
+		if err != nil {
+			Logger.Debugf("Failed to enqueue %s: %s", urlString, err)
+			return true
+		}
+		return alreadySeen
+	}
+	return crawler.urlSet.Duplicate(urlString)
+}
+
+func (crawler *Crawler) duplicateSub(sub string) bool {
+	if crawler.queue != nil {
+		if crawler.queue.SeenSub(sub) {
+			return true
+		}
+		crawler.queue.MarkSub(sub)
+		return false
+	}
+	return crawler.subSet.Duplicate(sub)
+}
+
+func (crawler *Crawler) duplicateJS(jsUrl string) bool {
+	if crawler.queue != nil {
+		if crawler.queue.SeenJS(jsUrl) {
+			return true
+		}
+		crawler.queue.MarkJS(jsUrl)
+		return false
+	}
+	return crawler.jsSet.Duplicate(jsUrl)
+}
+
+func (crawler *Crawler) duplicateForm(formUrl string) bool {
+	if crawler.queue != nil {
+		if crawler.queue.SeenForm(formUrl) {
+			return true
+		}
+		crawler.queue.MarkForm(formUrl)
+		return false
+	}
+	return crawler.formSet.Duplicate(formUrl)
+}
+
+func (crawler *Crawler) duplicateSecret(key string) bool {
+	if crawler.queue != nil {
+		if crawler.queue.SeenSecret(key) {
+			return true
+		}
+		crawler.queue.MarkSecret(key)
+		return false
 	}
+	return crawler.secretSet.Duplicate(key)
 }
 
 func (crawler *Crawler) Start() {
+	// Let the dashboard hold requests back while the crawl is paused.
+	if crawler.controller != nil {
+		crawler.C.OnRequest(func(r *colly.Request) {
+			crawler.controller.waitIfPaused()
+		})
+	}
+
 	// Handle url
 	crawler.C.OnHTML("[href]", func(e *colly.HTMLElement) {
 		urlString := e.Request.AbsoluteURL(e.Attr("href"))
@@ -209,7 +413,7 @@ func (crawler *Crawler) Start() {
 		if urlString == "" {
 			return
 		}
-		if !crawler.urlSet.Duplicate(urlString) {
+		if !crawler.duplicateURL(urlString) {
 			_ = e.Request.Visit(urlString)
 		}
 	})
@@ -222,13 +426,9 @@ func (crawler *Crawler) Start() {
 			return
 		}
 		// Just print
-		if !crawler.formSet.Duplicate(formUrl) {
+		if !crawler.duplicateForm(formUrl) {
 			if crawler.domainRe.MatchString(formUrl) {
-				outputFormat := fmt.Sprintf("[form] - %s", formUrl)
-				fmt.Println(outputFormat)
-				if crawler.Output != nil {
-					crawler.Output.WriteToFile(outputFormat)
-				}
+				crawler.emit(Finding{Type: "form", SourceURL: e.Request.URL.String(), Value: formUrl})
 			}
 		}
 	})
@@ -238,11 +438,7 @@ func (crawler *Crawler) Start() {
 	crawler.C.OnHTML(`input[type="file"]`, func(e *colly.HTMLElement) {
 		uploadUrl := e.Request.URL.String()
 		if !uploadFormSet.Duplicate(uploadUrl) {
-			outputFormat := fmt.Sprintf("[upload-form] - %s", uploadUrl)
-			fmt.Println(outputFormat)
-			if crawler.Output != nil {
-				crawler.Output.WriteToFile(outputFormat)
-			}
+			crawler.emit(Finding{Type: "upload-form", Value: uploadUrl})
 		}
 
 	})
@@ -257,12 +453,8 @@ func (crawler *Crawler) Start() {
 
 		fileExt := GetExtType(jsFileUrl)
 		if fileExt == ".js" || fileExt == ".xml" || fileExt == ".json" {
-			if !crawler.jsSet.Duplicate(jsFileUrl) {
-				outputFormat := fmt.Sprintf("[javascript] - %s", jsFileUrl)
-				fmt.Println(outputFormat)
-				if crawler.Output != nil {
-					crawler.Output.WriteToFile(outputFormat)
-				}
+			if !crawler.duplicateJS(jsFileUrl) {
+				crawler.emit(Finding{Type: "javascript", SourceURL: e.Request.URL.String(), Value: jsFileUrl})
 
 				// If JS file is minimal format. Try to find original format
 				if strings.Contains(jsFileUrl, ".min.js") {
@@ -279,16 +471,16 @@ func (crawler *Crawler) Start() {
 	crawler.C.OnResponse(func(response *colly.Response) {
 		respStr := DecodeChars(string(response.Body))
 
+		u := response.Request.URL.String()
 		crawler.findSubdomains(respStr)
-		crawler.findAWSS3(respStr)
+		crawler.scanSecrets(respStr, u)
 
-		// Verify which links are working
-		u := response.Request.URL.String()
-		outputFormat := fmt.Sprintf("[url] - [code-%d] - %s", response.StatusCode, u)
-		fmt.Println(outputFormat)
-		if crawler.Output != nil {
-			crawler.Output.WriteToFile(outputFormat)
+		if crawler.renderPool != nil {
+			crawler.renderPage(response.Request.URL, *response.Request.Headers)
 		}
+
+		// Verify which links are working
+		crawler.emit(Finding{Type: "url", Value: u, Status: response.StatusCode, Depth: response.Request.Depth})
 	})
 
 	crawler.C.OnError(func(response *colly.Response, err error) {
@@ -305,40 +497,84 @@ func (crawler *Crawler) Start() {
 		}
 
 		u := response.Request.URL.String()
-		outputFormat := fmt.Sprintf("[url] - [code-%d] - %s", response.StatusCode, u)
-		fmt.Println(outputFormat)
-		if crawler.Output != nil {
-			crawler.Output.WriteToFile(outputFormat)
-		}
+		crawler.emit(Finding{Type: "url", Value: u, Status: response.StatusCode, Depth: response.Request.Depth})
 	})
 
+	noPassiveSeeds, _ := crawler.cmd.Flags().GetBool("no-passive-seeds")
+	if !noPassiveSeeds {
+		crawler.discoverPassiveSeeds()
+	}
+
+	// Close the render pool unconditionally: it's needed on both the queue
+	// and non-queue branches below, and only one of them returns early.
+	if crawler.renderPool != nil {
+		defer crawler.renderPool.Close()
+	}
+
+	if crawler.queue != nil {
+		_, _ = crawler.queue.Enqueue(crawler.site.String())
+		crawler.drainQueue()
+		_ = crawler.queue.Close()
+		return
+	}
 	_ = crawler.C.Visit(crawler.site.String())
+	crawler.C.Wait()
+}
+
+// drainQueue pulls URLs off the persisted frontier and feeds them to the
+// collector. Visiting a URL can surface new ones (via duplicateURL), so we
+// keep passing over the frontier until a full pass leaves nothing pending.
+// Each dequeued url stays leased (see DiskVisitQueue.Dequeue) until C.Wait
+// confirms this whole batch has finished, only then do we Ack it — so a
+// process killed mid-batch resumes that batch's urls instead of losing them.
+func (crawler *Crawler) drainQueue() {
+	var batch []string
+	for {
+		u, ok, err := crawler.queue.Dequeue()
+		if err != nil {
+			Logger.Errorf("Resume queue error: %s", err)
+			return
+		}
+		if !ok {
+			break
+		}
+		batch = append(batch, u)
+		_ = crawler.C.Visit(u)
+	}
+	crawler.C.Wait()
+
+	for _, u := range batch {
+		if err := crawler.queue.Ack(u); err != nil {
+			Logger.Debugf("Failed to ack %s: %s", u, err)
+		}
+	}
+
+	if pending, err := crawler.queue.Pending(); err == nil && pending > 0 {
+		crawler.drainQueue()
+	}
 }
 
 // Find subdomains from response
 func (crawler *Crawler) findSubdomains(resp string) {
 	subs := GetSubdomains(resp, crawler.domain)
 	for _, sub := range subs {
-		if !crawler.subSet.Duplicate(sub) {
-			outputFormat := fmt.Sprintf("[subdomains] - %s", sub)
-			fmt.Println(outputFormat)
-			if crawler.Output != nil {
-				crawler.Output.WriteToFile(outputFormat)
-			}
+		if !crawler.duplicateSub(sub) {
+			crawler.emit(Finding{Type: "subdomains", Value: sub})
 		}
 	}
 }
 
-// Find AWS S3 from response
-func (crawler *Crawler) findAWSS3(resp string) {
-	aws := GetAWSS3(resp)
-	for _, e := range aws {
-		if !crawler.awsSet.Duplicate(e) {
-			outputFormat := fmt.Sprintf("[aws-s3] - %s", e)
-			fmt.Println(outputFormat)
-			if crawler.Output != nil {
-				crawler.Output.WriteToFile(outputFormat)
-			}
+// scanSecrets runs the pluggable Scanner (AWS-S3 buckets by default, plus
+// whatever --rules adds) over a response/JS body and emits one "secret"
+// Finding per match that survives dedup.
+func (crawler *Crawler) scanSecrets(resp, sourceURL string) {
+	if crawler.scanner == nil {
+		return
+	}
+	for _, m := range crawler.scanner.Scan(resp) {
+		key := m.Rule + ":" + m.Value
+		if !crawler.duplicateSecret(key) {
+			crawler.emit(Finding{Type: "secret", Rule: m.Rule, SourceURL: sourceURL, Value: m.Value})
 		}
 	}
 }
@@ -360,8 +596,8 @@ func (crawler *Crawler) linkFinder(jsUrl string) {
 
 	respStr := string(body)
 
-	crawler.findAWSS3(respStr)
 	crawler.findSubdomains(respStr)
+	crawler.scanSecrets(respStr, jsUrl)
 
 	links, err := LinkFinder(respStr)
 	if err != nil {
@@ -387,11 +623,7 @@ func (crawler *Crawler) linkFinder(jsUrl string) {
 		}
 
 		// JS Regex Result
-		outputFormat := fmt.Sprintf("[linkfinder] - [from: %s] - %s", jsUrl, link)
-		fmt.Println(outputFormat)
-		if crawler.Output != nil {
-			crawler.Output.WriteToFile(outputFormat)
-		}
+		crawler.emit(Finding{Type: "linkfinder", SourceURL: jsUrl, Value: link})
 		// Try to request JS path
 		_ = crawler.C.Visit(FixUrl(link, crawler.site))
 	}
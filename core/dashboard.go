@@ -0,0 +1,274 @@
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Controller owns a Crawler's colly.Collector and mediates every runtime
+// change the dashboard makes to it. colly gives URLFilters/Limit no
+// synchronization of its own against its async request workers, so
+// handleLimit/handleScope pause the crawl and let in-flight requests settle
+// before mutating anything, shrinking the race window instead of pretending
+// it isn't there.
+type Controller struct {
+	crawler *Crawler
+	paused  int32
+
+	visited, queuedJS, subdomains, secrets, aws, forms int64
+
+	scopeMu sync.Mutex
+	limitMu sync.Mutex
+
+	subsMu sync.Mutex
+	subs   map[chan string]struct{}
+}
+
+// NewController wires up a Controller for crawler and starts forwarding
+// every emitted Finding to it.
+func NewController(crawler *Crawler) *Controller {
+	ctrl := &Controller{crawler: crawler, subs: map[chan string]struct{}{}}
+	crawler.notify = ctrl.onFinding
+	return ctrl
+}
+
+// onFinding updates live counters and fans the finding out to every
+// connected SSE subscriber. Called from crawler.emit for every Finding.
+func (ctrl *Controller) onFinding(finding Finding) {
+	switch finding.Type {
+	case "url":
+		atomic.AddInt64(&ctrl.visited, 1)
+	case "javascript":
+		atomic.AddInt64(&ctrl.queuedJS, 1)
+	case "subdomains":
+		atomic.AddInt64(&ctrl.subdomains, 1)
+	case "secret":
+		atomic.AddInt64(&ctrl.secrets, 1)
+		if finding.Rule == "aws_s3" {
+			atomic.AddInt64(&ctrl.aws, 1)
+		}
+	case "form":
+		atomic.AddInt64(&ctrl.forms, 1)
+	}
+
+	data, err := json.Marshal(finding)
+	if err != nil {
+		return
+	}
+	msg := "data: " + string(data) + "\n\n"
+
+	ctrl.subsMu.Lock()
+	defer ctrl.subsMu.Unlock()
+	for ch := range ctrl.subs {
+		select {
+		case ch <- msg:
+		default:
+			Logger.Debugf("Dropped dashboard SSE event: subscriber too slow")
+		}
+	}
+}
+
+// waitIfPaused blocks the calling goroutine (a colly request) while the
+// crawl is paused from the dashboard.
+func (ctrl *Controller) waitIfPaused() {
+	for atomic.LoadInt32(&ctrl.paused) == 1 {
+		time.Sleep(200 * time.Millisecond)
+	}
+}
+
+// settleDelay is how long pauseAndSettle waits after pausing new requests
+// before it's safe enough to assume any request goroutine that was already
+// past waitIfPaused has finished reading URLFilters/Limit. colly gives us no
+// hook to know that for certain, so this is a best-effort window, not a
+// guarantee — see pauseAndSettle.
+const settleDelay = 250 * time.Millisecond
+
+// pauseAndSettle pauses the crawl and gives in-flight requests a moment to
+// clear before the caller mutates crawler.C's URLFilters/Limit state.
+// colly's async workers read that state with no synchronization of their
+// own, so this only shrinks the race window between a dashboard mutation
+// and a request goroutine already past waitIfPaused — it does not close it.
+// Callers resume the crawl once their mutation is done.
+func (ctrl *Controller) pauseAndSettle() {
+	atomic.StoreInt32(&ctrl.paused, 1)
+	time.Sleep(settleDelay)
+}
+
+func (ctrl *Controller) resume() {
+	atomic.StoreInt32(&ctrl.paused, 0)
+}
+
+type statsResponse struct {
+	Visited    int64 `json:"visited"`
+	JSFiles    int64 `json:"js_files"`
+	Subdomains int64 `json:"subdomains"`
+	Secrets    int64 `json:"secrets"`
+	AWSBuckets int64 `json:"aws_buckets"`
+	Forms      int64 `json:"forms"`
+	Paused     bool  `json:"paused"`
+}
+
+// Serve blocks, running the dashboard HTTP server on addr.
+func (ctrl *Controller) Serve(addr string) {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("/api/stats", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(statsResponse{
+			Visited:    atomic.LoadInt64(&ctrl.visited),
+			JSFiles:    atomic.LoadInt64(&ctrl.queuedJS),
+			Subdomains: atomic.LoadInt64(&ctrl.subdomains),
+			Secrets:    atomic.LoadInt64(&ctrl.secrets),
+			AWSBuckets: atomic.LoadInt64(&ctrl.aws),
+			Forms:      atomic.LoadInt64(&ctrl.forms),
+			Paused:     atomic.LoadInt32(&ctrl.paused) == 1,
+		})
+	})
+
+	mux.HandleFunc("/api/events", ctrl.handleEvents)
+
+	mux.HandleFunc("/api/pause", func(w http.ResponseWriter, r *http.Request) {
+		atomic.StoreInt32(&ctrl.paused, 1)
+		w.WriteHeader(http.StatusNoContent)
+	})
+	mux.HandleFunc("/api/resume", func(w http.ResponseWriter, r *http.Request) {
+		atomic.StoreInt32(&ctrl.paused, 0)
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	mux.HandleFunc("/api/limit", ctrl.handleLimit)
+	mux.HandleFunc("/api/scope", ctrl.handleScope)
+	mux.HandleFunc("/api/seed", ctrl.handleSeed)
+
+	Logger.Infof("Starting dashboard on %s", addr)
+	if err := http.ListenAndServe(addr, mux); err != nil {
+		Logger.Errorf("Dashboard server stopped: %s", err)
+	}
+}
+
+func (ctrl *Controller) handleEvents(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+
+	ch := make(chan string, 64)
+	ctrl.subsMu.Lock()
+	ctrl.subs[ch] = struct{}{}
+	ctrl.subsMu.Unlock()
+	defer func() {
+		ctrl.subsMu.Lock()
+		delete(ctrl.subs, ch)
+		ctrl.subsMu.Unlock()
+	}()
+
+	for {
+		select {
+		case msg := <-ch:
+			_, _ = fmt.Fprint(w, msg)
+			flusher.Flush()
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
+
+type limitRequest struct {
+	Concurrent  int `json:"concurrent"`
+	Delay       int `json:"delay"`
+	RandomDelay int `json:"random_delay"`
+}
+
+// handleLimit updates the concurrency/delay colly enforces at runtime.
+// colly.Collector.Limit only ever appends to its internal rule slice and
+// matches the first rule for a domain, so calling it again here would never
+// actually override the rule installed in NewCrawler — instead this mutates
+// that same *colly.LimitRule's fields in place, which colly's limiter reads
+// on every request.
+func (ctrl *Controller) handleLimit(w http.ResponseWriter, r *http.Request) {
+	var req limitRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	ctrl.limitMu.Lock()
+	defer ctrl.limitMu.Unlock()
+
+	ctrl.pauseAndSettle()
+	defer ctrl.resume()
+
+	rule := ctrl.crawler.limitRule
+	rule.Parallelism = req.Concurrent
+	rule.Delay = time.Duration(req.Delay) * time.Second
+	rule.RandomDelay = time.Duration(req.RandomDelay) * time.Second
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+type scopeRequest struct {
+	Add    string `json:"add"`
+	Remove string `json:"remove"`
+}
+
+// handleScope adds or removes an in-scope regex at runtime. colly has no
+// API to drop a URLFilter, so "remove" rebuilds the filter slice without it.
+// scopeMu only serializes concurrent dashboard requests against each other;
+// pauseAndSettle is what reduces (not eliminates) the race against colly's
+// own request workers reading URLFilters mid-crawl.
+func (ctrl *Controller) handleScope(w http.ResponseWriter, r *http.Request) {
+	var req scopeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	ctrl.scopeMu.Lock()
+	defer ctrl.scopeMu.Unlock()
+
+	ctrl.pauseAndSettle()
+	defer ctrl.resume()
+
+	if req.Add != "" {
+		re, err := regexp.Compile(req.Add)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		ctrl.crawler.C.URLFilters = append(ctrl.crawler.C.URLFilters, re)
+	}
+	if req.Remove != "" {
+		kept := ctrl.crawler.C.URLFilters[:0]
+		for _, re := range ctrl.crawler.C.URLFilters {
+			if re.String() != req.Remove {
+				kept = append(kept, re)
+			}
+		}
+		ctrl.crawler.C.URLFilters = kept
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+type seedRequest struct {
+	URL string `json:"url"`
+}
+
+func (ctrl *Controller) handleSeed(w http.ResponseWriter, r *http.Request) {
+	var req seedRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.URL == "" {
+		http.Error(w, "missing url", http.StatusBadRequest)
+		return
+	}
+	if !ctrl.crawler.duplicateURL(req.URL) {
+		_ = ctrl.crawler.C.Visit(req.URL)
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,44 @@
+package core
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gocolly/colly/v2"
+)
+
+func TestHandleLimitMutatesInstalledRule(t *testing.T) {
+	c := colly.NewCollector(colly.Async(true))
+	rule := &colly.LimitRule{DomainGlob: "*", Parallelism: 1}
+	if err := c.Limit(rule); err != nil {
+		t.Fatalf("Limit: %s", err)
+	}
+
+	ctrl := &Controller{crawler: &Crawler{C: c, domain: "*", limitRule: rule}}
+
+	body, _ := json.Marshal(limitRequest{Concurrent: 5, Delay: 2, RandomDelay: 1})
+	req := httptest.NewRequest(http.MethodPost, "/api/limit", bytes.NewReader(body))
+	w := httptest.NewRecorder()
+
+	ctrl.handleLimit(w, req)
+
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("handleLimit status = %d, want %d", w.Code, http.StatusNoContent)
+	}
+	// The same *colly.LimitRule colly installed in NewCrawler must be
+	// mutated in place: calling C.Limit again with a matching DomainGlob
+	// would silently append rather than replace it.
+	if rule.Parallelism != 5 {
+		t.Errorf("Parallelism = %d, want 5", rule.Parallelism)
+	}
+	if rule.Delay != 2*time.Second {
+		t.Errorf("Delay = %s, want 2s", rule.Delay)
+	}
+	if rule.RandomDelay != 1*time.Second {
+		t.Errorf("RandomDelay = %s, want 1s", rule.RandomDelay)
+	}
+}
@@ -0,0 +1,161 @@
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// Finding is one structured record emitted while crawling — a URL, form,
+// subdomain, JS file, etc. One Finding becomes exactly one line in
+// json/ndjson output, and one "[type] - value" line in text output.
+type Finding struct {
+	Type      string `json:"type"`
+	Rule      string `json:"rule,omitempty"`
+	SourceURL string `json:"source_url,omitempty"`
+	Value     string `json:"value"`
+	Status    int    `json:"status,omitempty"`
+	Depth     int    `json:"depth,omitempty"`
+	FoundAt   string `json:"found_at,omitempty"`
+	Ts        string `json:"ts"`
+}
+
+// line renders the finding the way --format text has always printed it.
+func (f Finding) line() string {
+	switch {
+	case f.Status != 0:
+		return fmt.Sprintf("[%s] - [code-%d] - %s", f.Type, f.Status, f.Value)
+	case f.Type == "linkfinder" && f.SourceURL != "":
+		return fmt.Sprintf("[%s] - [from: %s] - %s", f.Type, f.SourceURL, f.Value)
+	case f.Type == "secret":
+		return fmt.Sprintf("[%s] - [%s] - %s", f.Type, f.Rule, f.Value)
+	case f.Type == "seed":
+		return fmt.Sprintf("[%s] - %s - %s", f.Type, f.Rule, f.Value)
+	default:
+		return fmt.Sprintf("[%s] - %s", f.Type, f.Value)
+	}
+}
+
+// Output writes crawl findings to disk. In the default "text" format it
+// keeps gospider's original single-file `[tag] - value` output; with
+// --format json/ndjson it writes one JSON object per line instead, and
+// --split-output fans findings out into one file per Finding.Type under
+// the output folder.
+type Output struct {
+	mu     sync.Mutex
+	folder string
+	format string
+	split  bool
+
+	single *os.File
+	files  map[string]*os.File
+}
+
+// NewOutput prepares the output folder and, unless splitting, the single
+// file findings are appended to.
+func NewOutput(folder, filename, format string, split bool) *Output {
+	if err := os.MkdirAll(folder, 0755); err != nil {
+		Logger.Errorf("Failed to create output folder: %s", err)
+		return nil
+	}
+	if format == "" {
+		format = "text"
+	}
+	o := &Output{folder: folder, format: format, split: split, files: map[string]*os.File{}}
+
+	if !split {
+		f, err := os.OpenFile(filepath.Join(folder, filename+o.ext()), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+		if err != nil {
+			Logger.Errorf("Failed to open output file: %s", err)
+			return nil
+		}
+		o.single = f
+	}
+
+	return o
+}
+
+func (o *Output) ext() string {
+	if o.format == "text" {
+		return ".txt"
+	}
+	return ".jsonl"
+}
+
+// WriteToFile keeps the legacy entry point for callers that only have a
+// pre-formatted "[tag] - value" line and no structured Finding to report.
+func (o *Output) WriteToFile(line string) {
+	if o == nil || o.single == nil {
+		return
+	}
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	_, _ = o.single.WriteString(line + "\n")
+}
+
+// WriteFinding appends finding to the right file for the configured
+// format/split settings.
+func (o *Output) WriteFinding(finding Finding) {
+	if o == nil {
+		return
+	}
+
+	var line string
+	if o.format == "text" {
+		line = finding.line()
+	} else {
+		data, err := json.Marshal(finding)
+		if err != nil {
+			Logger.Errorf("Failed to marshal finding: %s", err)
+			return
+		}
+		line = string(data)
+	}
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	f := o.single
+	if o.split {
+		f = o.categoryFile(finding.Type)
+	}
+	if f == nil {
+		return
+	}
+	_, _ = f.WriteString(line + "\n")
+}
+
+func (o *Output) categoryFile(category string) *os.File {
+	if f, ok := o.files[category]; ok {
+		return f
+	}
+	f, err := os.OpenFile(filepath.Join(o.folder, category+o.ext()), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		Logger.Errorf("Failed to open %s output file: %s", category, err)
+		return nil
+	}
+	o.files[category] = f
+	return f
+}
+
+// Close flushes and releases every file handle the Output owns.
+func (o *Output) Close() {
+	if o == nil {
+		return
+	}
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.single != nil {
+		_ = o.single.Close()
+	}
+	for _, f := range o.files {
+		_ = f.Close()
+	}
+}
+
+func nowTimestamp() string {
+	return time.Now().UTC().Format(time.RFC3339)
+}
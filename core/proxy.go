@@ -0,0 +1,153 @@
+package core
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/elazarl/goproxy"
+	"github.com/gocolly/colly/v2"
+	"github.com/spf13/cobra"
+)
+
+// ProxyEvent carries one request/response pair observed by MITM while the
+// user manually browses a target through it.
+type ProxyEvent struct {
+	Request  *http.Request
+	Response *http.Response
+	Body     []byte
+}
+
+// MITM is a local HTTP/HTTPS man-in-the-middle proxy. Every request/response
+// flowing through it is turned into a ProxyEvent so a Crawler can seed its
+// frontier and scan bodies from whatever the user's browser actually sees,
+// including authenticated pages colly could never reach on its own.
+type MITM struct {
+	proxy  *goproxy.ProxyHttpServer
+	Events chan ProxyEvent
+}
+
+// NewMITM builds a MITM proxy, installing a self-signed CA under
+// ~/.gospider/ca the first time it runs so browsers can be pointed at it
+// after trusting that CA.
+func NewMITM() (*MITM, error) {
+	caDir, err := caDir()
+	if err != nil {
+		return nil, err
+	}
+	cert, err := loadOrCreateCA(caDir)
+	if err != nil {
+		return nil, fmt.Errorf("setup MITM CA: %w", err)
+	}
+	goproxy.GoproxyCa = *cert
+
+	proxy := goproxy.NewProxyHttpServer()
+	m := &MITM{proxy: proxy, Events: make(chan ProxyEvent, 256)}
+
+	proxy.OnRequest().HandleConnect(goproxy.AlwaysMitm)
+	proxy.OnResponse().DoFunc(func(resp *http.Response, ctx *goproxy.ProxyCtx) *http.Response {
+		if resp == nil {
+			return resp
+		}
+		body, err := ioutil.ReadAll(resp.Body)
+		if err != nil {
+			return resp
+		}
+		_ = resp.Body.Close()
+		resp.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+		select {
+		case m.Events <- ProxyEvent{Request: resp.Request, Response: resp, Body: body}:
+		default:
+			Logger.Debugf("Dropped proxy event for %s: events channel full", resp.Request.URL)
+		}
+		return resp
+	})
+
+	return m, nil
+}
+
+// Start runs the MITM proxy, blocking until it exits (it never returns nil
+// in practice; mirror the rest of the package's fatal-on-listen-error
+// convention rather than letting http.ListenAndServe's error vanish).
+func (m *MITM) Start(addr string) error {
+	Logger.Infof("Starting MITM proxy on %s", addr)
+	return http.ListenAndServe(addr, m.proxy)
+}
+
+func caDir() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	dir := filepath.Join(home, ".gospider", "ca")
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return "", err
+	}
+	return dir, nil
+}
+
+// FromProxyEvents builds a Crawler that is seeded and scanned from a live
+// MITM session instead of a single root URL. The first observed request's
+// host becomes the crawl domain; every subsequent event feeds the frontier
+// and is scanned the same way a colly response would be.
+func FromProxyEvents(site *url.URL, cmd *cobra.Command, events <-chan ProxyEvent) *Crawler {
+	crawler := NewCrawler(site, cmd)
+	go crawler.consumeProxyEvents(events)
+	return crawler
+}
+
+func (crawler *Crawler) consumeProxyEvents(events <-chan ProxyEvent) {
+	// Register the cookie forwarder once: colly accumulates OnRequest
+	// callbacks rather than replacing them, so registering one per event
+	// below would leak a closure per response and replay every stale
+	// cookie on every subsequent request.
+	var cookieMu sync.Mutex
+	var currentCookie string
+	crawler.C.OnRequest(func(r *colly.Request) {
+		cookieMu.Lock()
+		cookie := currentCookie
+		cookieMu.Unlock()
+		if cookie != "" {
+			r.Headers.Set("Cookie", cookie)
+		}
+	})
+
+	for ev := range events {
+		if ev.Request == nil {
+			continue
+		}
+		reqUrl := ev.Request.URL.String()
+		if !crawler.domainRe.MatchString(reqUrl) {
+			continue
+		}
+		if !crawler.duplicateURL(reqUrl) {
+			_ = crawler.C.Visit(reqUrl)
+		}
+
+		if ev.Response != nil {
+			if cookies := ev.Response.Cookies(); len(cookies) > 0 {
+				cookieMu.Lock()
+				currentCookie = GetRawCookie(cookies)
+				cookieMu.Unlock()
+			}
+		}
+
+		body := DecodeChars(string(ev.Body))
+		crawler.findSubdomains(body)
+		crawler.scanSecrets(body, reqUrl)
+
+		if links, err := LinkFinder(body); err == nil {
+			for _, link := range links {
+				if crawler.domainRe.MatchString(link) {
+					crawler.emit(Finding{Type: "linkfinder", SourceURL: reqUrl, Value: link})
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,197 @@
+package core
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/chromedp/cdproto/network"
+	"github.com/chromedp/chromedp"
+)
+
+// RenderPool drives a shared headless Chromium instance (via chromedp) used
+// to render JS-heavy pages that colly's plain HTTP fetcher can't see past.
+// Tabs are checked out of a bounded pool so --render never runs more
+// concurrent renders than --concurrent allows.
+type RenderPool struct {
+	allocCtx context.Context
+	cancel   context.CancelFunc
+	sem      chan struct{}
+	wait     string
+	timeout  time.Duration
+}
+
+// NewRenderPool launches a headless Chromium instance and returns a pool
+// that allows up to concurrent renders to run at once.
+func NewRenderPool(concurrent int, wait string, timeout time.Duration) *RenderPool {
+	if concurrent <= 0 {
+		concurrent = 1
+	}
+	allocCtx, cancel := chromedp.NewExecAllocator(context.Background(), chromedp.DefaultExecAllocatorOptions[:]...)
+	return &RenderPool{
+		allocCtx: allocCtx,
+		cancel:   cancel,
+		sem:      make(chan struct{}, concurrent),
+		wait:     wait,
+		timeout:  timeout,
+	}
+}
+
+// Close tears down the shared browser instance.
+func (p *RenderPool) Close() {
+	p.cancel()
+}
+
+// Render opens a tab, navigates to u with the given headers/cookie, waits
+// per the configured strategy, and returns the post-render DOM along with
+// every XHR/fetch URL observed while the page settled.
+func (p *RenderPool) Render(u string, headers http.Header, cookie string) (html string, xhrURLs []string, err error) {
+	p.sem <- struct{}{}
+	defer func() { <-p.sem }()
+
+	tabCtx, cancelTab := chromedp.NewContext(p.allocCtx)
+	defer cancelTab()
+	tabCtx, cancelTimeout := context.WithTimeout(tabCtx, p.timeout)
+	defer cancelTimeout()
+
+	var seen sync.Map
+	chromedp.ListenTarget(tabCtx, func(ev interface{}) {
+		if e, ok := ev.(*network.EventRequestWillBeSent); ok {
+			if _, dup := seen.LoadOrStore(e.Request.URL, true); !dup {
+				xhrURLs = append(xhrURLs, e.Request.URL)
+			}
+		}
+	})
+
+	extra := network.Headers{}
+	for k := range headers {
+		extra[k] = headers.Get(k)
+	}
+	if cookie != "" {
+		extra["Cookie"] = cookie
+	}
+
+	actions := []chromedp.Action{
+		network.Enable(),
+		network.SetExtraHTTPHeaders(extra),
+		chromedp.Navigate(u),
+	}
+	switch p.wait {
+	case "", "networkidle":
+		actions = append(actions, chromedp.Sleep(500*time.Millisecond))
+	default:
+		actions = append(actions, chromedp.WaitVisible(p.wait, chromedp.ByQuery))
+	}
+	actions = append(actions, chromedp.OuterHTML("html", &html, chromedp.ByQuery))
+
+	err = chromedp.Run(tabCtx, actions...)
+	return html, xhrURLs, err
+}
+
+// renderPage re-fetches response.Request.URL through the headless browser,
+// then feeds every [href]/form[action]/input[type="file"]/[src] found in
+// the rendered DOM (plus any XHR/fetch URLs observed along the way) back
+// into the same scope/dedup path as the plain-HTTP OnHTML handlers.
+func (crawler *Crawler) renderPage(pageURL *url.URL, headers http.Header) {
+	cookie := headers.Get("Cookie")
+	html, xhrURLs, err := crawler.renderPool.Render(pageURL.String(), headers, cookie)
+	if err != nil {
+		Logger.Debugf("Render failed for %s: %s", pageURL, err)
+		return
+	}
+
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(html))
+	if err != nil {
+		Logger.Debugf("Failed to parse rendered DOM for %s: %s", pageURL, err)
+		return
+	}
+
+	doc.Find("[href]").Each(func(_ int, s *goquery.Selection) {
+		href, _ := s.Attr("href")
+		crawler.visitRendered(pageURL, href)
+	})
+	doc.Find(`form[action]`).Each(func(_ int, s *goquery.Selection) {
+		action, _ := s.Attr("action")
+		crawler.formFoundRendered(pageURL, action)
+	})
+	doc.Find(`input[type="file"]`).Each(func(_ int, _ *goquery.Selection) {
+		crawler.emit(Finding{Type: "upload-form", Value: pageURL.String()})
+	})
+	doc.Find("[src]").Each(func(_ int, s *goquery.Selection) {
+		src, _ := s.Attr("src")
+		crawler.jsFoundRendered(pageURL, src)
+	})
+
+	// SPA-only endpoints never show up in the DOM at all; seed them too.
+	for _, xhrURL := range xhrURLs {
+		crawler.visitRendered(pageURL, xhrURL)
+	}
+}
+
+// resolveRendered turns an href/src/action attribute seen in a rendered DOM
+// into an absolute, in-scope URL, the same way e.Request.AbsoluteURL+FixUrl
+// does for the plain-HTTP OnHTML handlers.
+func (crawler *Crawler) resolveRendered(pageURL *url.URL, raw string) string {
+	if raw == "" {
+		return ""
+	}
+	resolved, err := pageURL.Parse(raw)
+	if err != nil {
+		return ""
+	}
+	return FixUrl(resolved.String(), crawler.site)
+}
+
+func (crawler *Crawler) visitRendered(pageURL *url.URL, raw string) {
+	fixed := crawler.resolveRendered(pageURL, raw)
+	if fixed == "" {
+		return
+	}
+	if !crawler.duplicateURL(fixed) {
+		_ = crawler.C.Visit(fixed)
+	}
+}
+
+// formFoundRendered mirrors the plain-HTTP form[action] OnHTML handler for a
+// form seen only after JS rendering.
+func (crawler *Crawler) formFoundRendered(pageURL *url.URL, raw string) {
+	formUrl := crawler.resolveRendered(pageURL, raw)
+	if formUrl == "" {
+		return
+	}
+	if !crawler.duplicateForm(formUrl) {
+		if crawler.domainRe.MatchString(formUrl) {
+			crawler.emit(Finding{Type: "form", SourceURL: pageURL.String(), Value: formUrl})
+		}
+	}
+}
+
+// jsFoundRendered mirrors the plain-HTTP [src] OnHTML handler for a
+// js/xml/json source seen only after JS rendering: other src attributes
+// (images, etc.) are just visited like any other link.
+func (crawler *Crawler) jsFoundRendered(pageURL *url.URL, raw string) {
+	jsFileUrl := crawler.resolveRendered(pageURL, raw)
+	if jsFileUrl == "" {
+		return
+	}
+
+	fileExt := GetExtType(jsFileUrl)
+	if fileExt != ".js" && fileExt != ".xml" && fileExt != ".json" {
+		crawler.visitRendered(pageURL, raw)
+		return
+	}
+
+	if crawler.duplicateJS(jsFileUrl) {
+		return
+	}
+	crawler.emit(Finding{Type: "javascript", SourceURL: pageURL.String(), Value: jsFileUrl})
+
+	if strings.Contains(jsFileUrl, ".min.js") {
+		crawler.linkFinder(strings.ReplaceAll(jsFileUrl, ".min.js", ".js"))
+	}
+	crawler.linkFinder(jsFileUrl)
+}
@@ -0,0 +1,180 @@
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// Rule is one named pattern the Scanner looks for in response/JS bodies.
+// MinEntropy filters out low-entropy false positives (e.g. a "key: xxxx"
+// placeholder); Allow/Deny let a rule require or reject extra context
+// around a match without needing a more convoluted single regex.
+type Rule struct {
+	Name       string  `yaml:"name" json:"name"`
+	Pattern    string  `yaml:"pattern" json:"pattern"`
+	MinEntropy float64 `yaml:"min_entropy,omitempty" json:"min_entropy,omitempty"`
+	Allow      string  `yaml:"allow,omitempty" json:"allow,omitempty"`
+	Deny       string  `yaml:"deny,omitempty" json:"deny,omitempty"`
+
+	re      *regexp.Regexp
+	allowRe *regexp.Regexp
+	denyRe  *regexp.Regexp
+}
+
+// defaultRules ships inline so --rules stays optional: without it, Scanner
+// behaves the same as gospider's old AWS-S3-only scanning always did.
+var defaultRules = []Rule{
+	{
+		Name:    "aws_s3",
+		Pattern: `(?i)[a-z0-9.\-]+\.s3\.amazonaws\.com|s3://[a-z0-9.\-]+|s3-[a-z0-9.\-]+\.amazonaws\.com|[a-z0-9.\-]+\.s3-[a-z0-9.\-]+\.amazonaws\.com`,
+	},
+	{Name: "aws_access_key", Pattern: `(?:A3T[A-Z0-9]|AKIA|AGPA|AIDA|AROA|AIPA|ANPA|ANVA|ASIA)[A-Z0-9]{16}`},
+	{Name: "google_api_key", Pattern: `AIza[0-9A-Za-z\-_]{35}`},
+	{Name: "slack_webhook", Pattern: `https://hooks\.slack\.com/services/T[a-zA-Z0-9_]+/B[a-zA-Z0-9_]+/[a-zA-Z0-9_]+`},
+	{Name: "stripe_key", Pattern: `(?:sk|rk)_(?:live|test)_[0-9a-zA-Z]{24,}`},
+	{Name: "jwt", Pattern: `eyJ[A-Za-z0-9_-]+\.eyJ[A-Za-z0-9_-]+\.[A-Za-z0-9_-]+`},
+	{Name: "private_key_pem", Pattern: `-----BEGIN (?:RSA |EC |DSA |OPENSSH )?PRIVATE KEY-----`},
+	{Name: "gcp_service_account", Pattern: `"type":\s*"service_account"`},
+}
+
+// Scanner runs every enabled Rule over a body and reports the matches as
+// secret Findings. It replaces the old single-purpose findAWSS3 helper
+// with something recon pipelines can extend via a rules file, with no
+// gospider rebuild required.
+type Scanner struct {
+	rules []*Rule
+}
+
+// secretMatch is one hit of a Rule against a body.
+type secretMatch struct {
+	Rule  string
+	Value string
+}
+
+// NewScanner compiles the built-in rules plus, if rulesFile is non-empty,
+// every rule loaded from it (YAML by default, JSON if the file ends in
+// .json).
+func NewScanner(rulesFile string) (*Scanner, error) {
+	rules := make([]Rule, len(defaultRules))
+	copy(rules, defaultRules)
+
+	if rulesFile != "" {
+		loaded, err := loadRulesFile(rulesFile)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, loaded...)
+	}
+
+	s := &Scanner{}
+	for i := range rules {
+		r := rules[i]
+		re, err := regexp.Compile(r.Pattern)
+		if err != nil {
+			Logger.Errorf("Skipping rule %q: bad pattern: %s", r.Name, err)
+			continue
+		}
+		r.re = re
+
+		if r.Allow != "" {
+			if r.allowRe, err = regexp.Compile(r.Allow); err != nil {
+				Logger.Errorf("Rule %q: bad allow pattern: %s", r.Name, err)
+			}
+		}
+		if r.Deny != "" {
+			if r.denyRe, err = regexp.Compile(r.Deny); err != nil {
+				Logger.Errorf("Rule %q: bad deny pattern: %s", r.Name, err)
+			}
+		}
+		s.rules = append(s.rules, &r)
+	}
+	return s, nil
+}
+
+func loadRulesFile(path string) ([]Rule, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read rules file: %w", err)
+	}
+
+	var doc struct {
+		Rules []Rule `yaml:"rules" json:"rules"`
+	}
+	if strings.HasSuffix(strings.ToLower(path), ".json") {
+		if err := json.Unmarshal(data, &doc); err != nil {
+			return nil, fmt.Errorf("parse rules json: %w", err)
+		}
+	} else {
+		if err := yaml.Unmarshal(data, &doc); err != nil {
+			return nil, fmt.Errorf("parse rules yaml: %w", err)
+		}
+	}
+	return doc.Rules, nil
+}
+
+// contextRadius bounds how much of body on either side of a match
+// Allow/Deny are checked against — enough to catch a neighboring variable
+// name or comment without scanning the whole body for every hit.
+const contextRadius = 40
+
+// context returns the body slice within contextRadius of [start, end), the
+// "extra context around a match" Allow/Deny are documented to test.
+func context(body string, start, end int) string {
+	lo := start - contextRadius
+	if lo < 0 {
+		lo = 0
+	}
+	hi := end + contextRadius
+	if hi > len(body) {
+		hi = len(body)
+	}
+	return body[lo:hi]
+}
+
+// Scan runs every rule over body and returns one secretMatch per hit that
+// clears its rule's entropy floor and allow/deny context.
+func (s *Scanner) Scan(body string) []secretMatch {
+	var matches []secretMatch
+	for _, r := range s.rules {
+		for _, loc := range r.re.FindAllStringIndex(body, -1) {
+			value := body[loc[0]:loc[1]]
+			ctx := context(body, loc[0], loc[1])
+			if r.allowRe != nil && !r.allowRe.MatchString(ctx) {
+				continue
+			}
+			if r.denyRe != nil && r.denyRe.MatchString(ctx) {
+				continue
+			}
+			if r.MinEntropy > 0 && shannonEntropy(value) < r.MinEntropy {
+				continue
+			}
+			matches = append(matches, secretMatch{Rule: r.Name, Value: value})
+		}
+	}
+	return matches
+}
+
+// shannonEntropy reports the Shannon entropy (bits/char) of s, used to
+// reject low-entropy matches like "sk_test_XXXXXXXXXXXXXXXXXXXXXXXX".
+func shannonEntropy(s string) float64 {
+	if s == "" {
+		return 0
+	}
+	counts := map[rune]float64{}
+	for _, r := range s {
+		counts[r]++
+	}
+	n := float64(len(s))
+	var entropy float64
+	for _, c := range counts {
+		p := c / n
+		entropy -= p * math.Log2(p)
+	}
+	return entropy
+}
@@ -0,0 +1,146 @@
+package core
+
+import (
+	"encoding/xml"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// sitemapURLSet matches a leaf sitemap.xml: <urlset><url><loc>...
+type sitemapURLSet struct {
+	URLs []struct {
+		Loc string `xml:"loc"`
+	} `xml:"url"`
+}
+
+// sitemapIndex matches a sitemap index: <sitemapindex><sitemap><loc>...
+type sitemapIndex struct {
+	Sitemaps []struct {
+		Loc string `xml:"loc"`
+	} `xml:"sitemap"`
+}
+
+// discoverPassiveSeeds fetches robots.txt, sitemap(s), and a handful of
+// well-known/GraphQL endpoints before the crawl proper starts, seeding the
+// frontier with whatever URLs they already publish. Sites that bother to
+// publish a sitemap routinely list far more of their own surface than a
+// single root URL crawl would ever reach.
+func (crawler *Crawler) discoverPassiveSeeds() {
+	base := crawler.site.Scheme + "://" + crawler.site.Host
+	// Reuse the same transport (and thus --proxy) colly itself uses, so
+	// passive-seed requests don't silently bypass it.
+	client := &http.Client{Transport: crawler.httpTransport, Timeout: 15 * time.Second}
+
+	crawler.seedFromRobotsTxt(client, base+"/robots.txt")
+	crawler.seedFromSitemap(client, base+"/sitemap.xml", map[string]bool{})
+	crawler.seedFromSitemap(client, base+"/sitemap_index.xml", map[string]bool{})
+
+	for _, path := range []string{
+		"/.well-known/security.txt",
+		"/.well-known/openid-configuration",
+		"/graphql?query={__typename}",
+		"/graphiql",
+	} {
+		crawler.seed(".well-known", base+path)
+	}
+}
+
+// seed resolves rawURL into scope, emits a "seed" Finding recording where
+// it came from, and feeds it into the collector like any other discovered
+// link. In --resume mode duplicateURL() already enqueues fixed onto the
+// persisted frontier, so drainQueue() is what will eventually visit it;
+// visiting it here too would crawl it twice.
+func (crawler *Crawler) seed(source, rawURL string) {
+	fixed := FixUrl(rawURL, crawler.site)
+	if fixed == "" || crawler.duplicateURL(fixed) {
+		return
+	}
+	crawler.emit(Finding{Type: "seed", Rule: source, Value: fixed})
+	if crawler.queue == nil {
+		_ = crawler.C.Visit(fixed)
+	}
+}
+
+// get issues a GET with the same cookie/header setup colly itself carries,
+// so passive-seed requests see what an authenticated crawl would.
+func (crawler *Crawler) get(client *http.Client, rawURL string) (*http.Response, error) {
+	req, err := http.NewRequest(http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range crawler.extraHeaders {
+		req.Header.Set(k, v)
+	}
+	return client.Do(req)
+}
+
+func (crawler *Crawler) seedFromRobotsTxt(client *http.Client, robotsURL string) {
+	resp, err := crawler.get(client, robotsURL)
+	if err != nil {
+		return
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != 200 {
+		return
+	}
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return
+	}
+
+	for _, line := range strings.Split(string(body), "\n") {
+		line = strings.TrimSpace(line)
+		lower := strings.ToLower(line)
+		switch {
+		case strings.HasPrefix(lower, "disallow:"):
+			path := strings.TrimSpace(line[len("disallow:"):])
+			if path != "" && path != "/" {
+				crawler.seed("robots.txt", crawler.site.Scheme+"://"+crawler.site.Host+path)
+			}
+		case strings.HasPrefix(lower, "sitemap:"):
+			if sitemapURL := strings.TrimSpace(line[len("sitemap:"):]); sitemapURL != "" {
+				crawler.seedFromSitemap(client, sitemapURL, map[string]bool{})
+			}
+		}
+	}
+}
+
+// seedFromSitemap fetches sitemapURL and recurses into it if it's a
+// sitemap index; visited guards against index loops pointing at themselves.
+func (crawler *Crawler) seedFromSitemap(client *http.Client, sitemapURL string, visited map[string]bool) {
+	if visited[sitemapURL] {
+		return
+	}
+	visited[sitemapURL] = true
+
+	resp, err := crawler.get(client, sitemapURL)
+	if err != nil {
+		return
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != 200 {
+		return
+	}
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return
+	}
+
+	var index sitemapIndex
+	if err := xml.Unmarshal(body, &index); err == nil && len(index.Sitemaps) > 0 {
+		for _, sm := range index.Sitemaps {
+			crawler.seedFromSitemap(client, sm.Loc, visited)
+		}
+		return
+	}
+
+	var urlset sitemapURLSet
+	if err := xml.Unmarshal(body, &urlset); err != nil {
+		return
+	}
+	for _, u := range urlset.URLs {
+		crawler.seed(sitemapURL, u.Loc)
+	}
+}
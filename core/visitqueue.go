@@ -0,0 +1,242 @@
+package core
+
+import (
+	"encoding/binary"
+	"fmt"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+var (
+	frontierBucket      = []byte("frontier")
+	frontierSeenBucket  = []byte("frontier_seen")
+	frontierLeaseBucket = []byte("frontier_lease")
+	subBucket           = []byte("seen_sub")
+	jsBucket            = []byte("seen_js")
+	formBucket          = []byte("seen_form")
+	secretBucket        = []byte("seen_secret")
+)
+
+// VisitQueue is the persistence contract for the crawl frontier and its
+// dedup state. It lets Crawler swap the in-memory stringset.StringFilter
+// sets for an on-disk store so --resume can pick a killed crawl back up
+// without re-walking everything it already saw.
+type VisitQueue interface {
+	// Enqueue adds url to the frontier. alreadySeen is true if url was
+	// already enqueued or dequeued before, in which case this call was a
+	// no-op; callers use it the same way stringset.StringFilter.Duplicate
+	// is used for the in-memory sets.
+	Enqueue(url string) (alreadySeen bool, err error)
+	// Dequeue pops the next pending url and leases it: it won't be handed
+	// out again, but it also isn't gone for good until Ack confirms it was
+	// fully visited. ok is false when the frontier is currently empty.
+	Dequeue() (u string, ok bool, err error)
+	// Ack confirms url (previously returned by Dequeue) was fully visited,
+	// clearing its lease for good. A leased-but-unacked url is put back on
+	// the frontier the next time this database is opened, so a process
+	// killed mid-visit doesn't lose it.
+	Ack(url string) error
+	// Pending reports how many urls are still waiting in the frontier.
+	Pending() (int, error)
+
+	SeenSub(sub string) bool
+	MarkSub(sub string)
+	SeenJS(u string) bool
+	MarkJS(u string)
+	SeenForm(u string) bool
+	MarkForm(u string)
+	SeenSecret(key string) bool
+	MarkSecret(key string)
+
+	Close() error
+}
+
+// DiskVisitQueue backs VisitQueue with a bbolt database so large crawls
+// don't have to hold every seen URL in RAM. By default bbolt fsyncs every
+// single transaction, which is crash-safe but slow under a crawl doing many
+// small enqueue/mark writes per second; flushInterval, when set, trades that
+// per-commit fsync for a background goroutine that syncs on a timer instead.
+type DiskVisitQueue struct {
+	db           *bolt.DB
+	maxQueueSize int
+
+	stopFlush chan struct{}
+}
+
+// NewDiskVisitQueue opens (or creates) the resume database under dir.
+// maxQueueSize bounds how many URLs may sit in the frontier at once; 0
+// means unbounded. flushInterval, if > 0, disables bbolt's per-transaction
+// fsync in favor of an explicit Sync on that interval; 0 keeps bbolt's
+// default of fsyncing every commit.
+func NewDiskVisitQueue(dir string, maxQueueSize int, flushInterval time.Duration) (*DiskVisitQueue, error) {
+	db, err := bolt.Open(dir+"/resume.db", 0644, &bolt.Options{Timeout: 5 * time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("open resume db: %w", err)
+	}
+
+	q := &DiskVisitQueue{db: db, maxQueueSize: maxQueueSize}
+
+	if flushInterval > 0 {
+		db.NoSync = true
+		q.stopFlush = make(chan struct{})
+		go q.periodicFlush(flushInterval)
+	}
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		for _, b := range [][]byte{frontierBucket, frontierSeenBucket, frontierLeaseBucket, subBucket, jsBucket, formBucket, secretBucket} {
+			if _, err := tx.CreateBucketIfNotExists(b); err != nil {
+				return err
+			}
+		}
+		// Anything still leased from a previous run was dequeued but never
+		// acked, i.e. the process died before confirming the visit
+		// completed. Put it back on the frontier so this run retries it.
+		return requeueLeased(tx)
+	})
+	if err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("init resume buckets: %w", err)
+	}
+
+	return q, nil
+}
+
+func requeueLeased(tx *bolt.Tx) error {
+	lease := tx.Bucket(frontierLeaseBucket)
+	frontier := tx.Bucket(frontierBucket)
+
+	var leased [][]byte
+	if err := lease.ForEach(func(k, _ []byte) error {
+		leased = append(leased, append([]byte{}, k...))
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	for _, url := range leased {
+		seq, _ := frontier.NextSequence()
+		key := make([]byte, 8)
+		binary.BigEndian.PutUint64(key, seq)
+		if err := frontier.Put(key, url); err != nil {
+			return err
+		}
+		if err := lease.Delete(url); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// periodicFlush fsyncs the database on a timer while db.NoSync is set,
+// bounding how much would be lost to a crash between syncs to roughly
+// flushInterval's worth of writes.
+func (q *DiskVisitQueue) periodicFlush(flushInterval time.Duration) {
+	ticker := time.NewTicker(flushInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if err := q.db.Sync(); err != nil {
+				Logger.Debugf("Resume queue periodic sync failed: %s", err)
+			}
+		case <-q.stopFlush:
+			return
+		}
+	}
+}
+
+func (q *DiskVisitQueue) Enqueue(url string) (bool, error) {
+	var alreadySeen bool
+	err := q.db.Update(func(tx *bolt.Tx) error {
+		seen := tx.Bucket(frontierSeenBucket)
+		if seen.Get([]byte(url)) != nil {
+			alreadySeen = true
+			return nil
+		}
+
+		frontier := tx.Bucket(frontierBucket)
+		if q.maxQueueSize > 0 && frontier.Stats().KeyN >= q.maxQueueSize {
+			return fmt.Errorf("resume queue full (max %d)", q.maxQueueSize)
+		}
+
+		seq, _ := frontier.NextSequence()
+		key := make([]byte, 8)
+		binary.BigEndian.PutUint64(key, seq)
+		if err := frontier.Put(key, []byte(url)); err != nil {
+			return err
+		}
+		return seen.Put([]byte(url), []byte{1})
+	})
+	return alreadySeen, err
+}
+
+// Dequeue pops the oldest pending url off the frontier and leases it rather
+// than deleting it outright, so a kill between Dequeue and the matching Ack
+// doesn't lose it: requeueLeased puts it back on the frontier the next time
+// this database is opened.
+func (q *DiskVisitQueue) Dequeue() (string, bool, error) {
+	var u string
+	var ok bool
+	err := q.db.Update(func(tx *bolt.Tx) error {
+		frontier := tx.Bucket(frontierBucket)
+		c := frontier.Cursor()
+		k, v := c.First()
+		if k == nil {
+			return nil
+		}
+		u = string(v)
+		ok = true
+		if err := tx.Bucket(frontierLeaseBucket).Put(v, []byte{1}); err != nil {
+			return err
+		}
+		return frontier.Delete(k)
+	})
+	return u, ok, err
+}
+
+func (q *DiskVisitQueue) Ack(url string) error {
+	return q.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(frontierLeaseBucket).Delete([]byte(url))
+	})
+}
+
+func (q *DiskVisitQueue) Pending() (int, error) {
+	n := 0
+	err := q.db.View(func(tx *bolt.Tx) error {
+		n = tx.Bucket(frontierBucket).Stats().KeyN
+		return nil
+	})
+	return n, err
+}
+
+func (q *DiskVisitQueue) seen(bucket []byte, key string) bool {
+	var found bool
+	_ = q.db.View(func(tx *bolt.Tx) error {
+		found = tx.Bucket(bucket).Get([]byte(key)) != nil
+		return nil
+	})
+	return found
+}
+
+func (q *DiskVisitQueue) mark(bucket []byte, key string) {
+	_ = q.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(bucket).Put([]byte(key), []byte{1})
+	})
+}
+
+func (q *DiskVisitQueue) SeenSub(sub string) bool    { return q.seen(subBucket, sub) }
+func (q *DiskVisitQueue) MarkSub(sub string)         { q.mark(subBucket, sub) }
+func (q *DiskVisitQueue) SeenJS(u string) bool       { return q.seen(jsBucket, u) }
+func (q *DiskVisitQueue) MarkJS(u string)            { q.mark(jsBucket, u) }
+func (q *DiskVisitQueue) SeenForm(u string) bool     { return q.seen(formBucket, u) }
+func (q *DiskVisitQueue) MarkForm(u string)          { q.mark(formBucket, u) }
+func (q *DiskVisitQueue) SeenSecret(key string) bool { return q.seen(secretBucket, key) }
+func (q *DiskVisitQueue) MarkSecret(key string)      { q.mark(secretBucket, key) }
+
+func (q *DiskVisitQueue) Close() error {
+	if q.stopFlush != nil {
+		close(q.stopFlush)
+	}
+	return q.db.Close()
+}
@@ -0,0 +1,178 @@
+package core
+
+import "testing"
+
+func newTestQueue(t *testing.T) *DiskVisitQueue {
+	t.Helper()
+	q, err := NewDiskVisitQueue(t.TempDir(), 0, 0)
+	if err != nil {
+		t.Fatalf("NewDiskVisitQueue: %s", err)
+	}
+	t.Cleanup(func() { _ = q.Close() })
+	return q
+}
+
+func TestDiskVisitQueueEnqueueDedup(t *testing.T) {
+	q := newTestQueue(t)
+
+	alreadySeen, err := q.Enqueue("https://example.com/a")
+	if err != nil {
+		t.Fatalf("Enqueue: %s", err)
+	}
+	if alreadySeen {
+		t.Fatal("first Enqueue of a fresh URL reported alreadySeen")
+	}
+
+	alreadySeen, err = q.Enqueue("https://example.com/a")
+	if err != nil {
+		t.Fatalf("Enqueue: %s", err)
+	}
+	if !alreadySeen {
+		t.Fatal("re-enqueuing the same URL did not report alreadySeen")
+	}
+}
+
+func TestDiskVisitQueueEnqueueDequeueOrder(t *testing.T) {
+	q := newTestQueue(t)
+
+	want := []string{"https://example.com/a", "https://example.com/b", "https://example.com/c"}
+	for _, u := range want {
+		if _, err := q.Enqueue(u); err != nil {
+			t.Fatalf("Enqueue(%s): %s", u, err)
+		}
+	}
+
+	if pending, err := q.Pending(); err != nil || pending != len(want) {
+		t.Fatalf("Pending() = %d, %v, want %d, nil", pending, err, len(want))
+	}
+
+	for _, w := range want {
+		u, ok, err := q.Dequeue()
+		if err != nil {
+			t.Fatalf("Dequeue: %s", err)
+		}
+		if !ok {
+			t.Fatalf("Dequeue reported empty frontier before draining %q", w)
+		}
+		if u != w {
+			t.Fatalf("Dequeue() = %q, want %q", u, w)
+		}
+	}
+
+	if _, ok, err := q.Dequeue(); err != nil || ok {
+		t.Fatalf("Dequeue on an empty frontier = ok=%v, err=%v, want ok=false, err=nil", ok, err)
+	}
+}
+
+func TestDiskVisitQueueEnqueueMaxSize(t *testing.T) {
+	q, err := NewDiskVisitQueue(t.TempDir(), 1, 0)
+	if err != nil {
+		t.Fatalf("NewDiskVisitQueue: %s", err)
+	}
+	defer q.Close()
+
+	if _, err := q.Enqueue("https://example.com/a"); err != nil {
+		t.Fatalf("Enqueue under the limit: %s", err)
+	}
+	if _, err := q.Enqueue("https://example.com/b"); err == nil {
+		t.Fatal("Enqueue past maxQueueSize did not return an error")
+	}
+}
+
+func TestDiskVisitQueueUnackedDequeueIsRequeuedOnReopen(t *testing.T) {
+	dir := t.TempDir()
+
+	q, err := NewDiskVisitQueue(dir, 0, 0)
+	if err != nil {
+		t.Fatalf("NewDiskVisitQueue: %s", err)
+	}
+	if _, err := q.Enqueue("https://example.com/a"); err != nil {
+		t.Fatalf("Enqueue: %s", err)
+	}
+	u, ok, err := q.Dequeue()
+	if err != nil || !ok || u != "https://example.com/a" {
+		t.Fatalf("Dequeue() = %q, %v, %v", u, ok, err)
+	}
+	// Simulate a crash between Dequeue and Ack: close without acking.
+	if err := q.Close(); err != nil {
+		t.Fatalf("Close: %s", err)
+	}
+
+	q2, err := NewDiskVisitQueue(dir, 0, 0)
+	if err != nil {
+		t.Fatalf("reopen NewDiskVisitQueue: %s", err)
+	}
+	defer q2.Close()
+
+	pending, err := q2.Pending()
+	if err != nil {
+		t.Fatalf("Pending: %s", err)
+	}
+	if pending != 1 {
+		t.Fatalf("Pending() after reopen = %d, want 1 (unacked dequeue should be requeued)", pending)
+	}
+	u, ok, err = q2.Dequeue()
+	if err != nil || !ok || u != "https://example.com/a" {
+		t.Fatalf("Dequeue() after reopen = %q, %v, %v, want the requeued url", u, ok, err)
+	}
+}
+
+func TestDiskVisitQueueAckedDequeueIsNotRequeuedOnReopen(t *testing.T) {
+	dir := t.TempDir()
+
+	q, err := NewDiskVisitQueue(dir, 0, 0)
+	if err != nil {
+		t.Fatalf("NewDiskVisitQueue: %s", err)
+	}
+	if _, err := q.Enqueue("https://example.com/a"); err != nil {
+		t.Fatalf("Enqueue: %s", err)
+	}
+	u, ok, err := q.Dequeue()
+	if err != nil || !ok || u != "https://example.com/a" {
+		t.Fatalf("Dequeue() = %q, %v, %v", u, ok, err)
+	}
+	if err := q.Ack(u); err != nil {
+		t.Fatalf("Ack: %s", err)
+	}
+	if err := q.Close(); err != nil {
+		t.Fatalf("Close: %s", err)
+	}
+
+	q2, err := NewDiskVisitQueue(dir, 0, 0)
+	if err != nil {
+		t.Fatalf("reopen NewDiskVisitQueue: %s", err)
+	}
+	defer q2.Close()
+
+	if pending, err := q2.Pending(); err != nil || pending != 0 {
+		t.Fatalf("Pending() after reopen = %d, %v, want 0 (acked dequeue should stay gone)", pending, err)
+	}
+}
+
+func TestDiskVisitQueueSeenMark(t *testing.T) {
+	q := newTestQueue(t)
+
+	cases := []struct {
+		name string
+		seen func(string) bool
+		mark func(string)
+	}{
+		{"sub", q.SeenSub, q.MarkSub},
+		{"js", q.SeenJS, q.MarkJS},
+		{"form", q.SeenForm, q.MarkForm},
+		{"secret", q.SeenSecret, q.MarkSecret},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			key := "key-" + c.name
+			if c.seen(key) {
+				t.Fatalf("%s reported seen before Mark", c.name)
+			}
+			c.mark(key)
+			if !c.seen(key) {
+				t.Fatalf("%s reported unseen after Mark", c.name)
+			}
+		})
+	}
+}